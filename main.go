@@ -2,137 +2,379 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/krognol/go-wolfram"
 	"github.com/shomali11/slacker"
-	"github.com/tidwall/gjson"
 
-	witai "github.com/wit-ai/wit-go/v2"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/authz"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/config"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/logging"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/memory"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/metrics"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/pipeline"
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/render"
 )
 
-var wolframClient *wolfram.Client
-
-func printCommandEvents(analyticsChannel <-chan *slacker.CommandEvent) {
+func logCommandEvents(logger *slog.Logger, analyticsChannel <-chan *slacker.CommandEvent) {
 	for event := range analyticsChannel {
-		fmt.Println("Command Events")
-		fmt.Println(event.Timestamp)
-		fmt.Println(event.Command)
-		fmt.Println(event.Parameters)
-		fmt.Println(event.Event)
-		fmt.Println()
+		logging.LogCommandEvent(logger, event)
 	}
 }
 
 func main() {
 	godotenv.Load(".env")
 
+	logger := logging.New()
+
+	configPath := flag.String("config", envOrDefault("BOT_CONFIG", "bot.yaml"), "path to the YAML bot command config")
+	flag.Parse()
+
+	configStore, err := config.NewStore(*configPath)
+	if err != nil {
+		log.Fatalf("error loading config %s: %v", *configPath, err)
+	}
+	configStore.WatchSIGHUP() // reload authorization lists/templates on SIGHUP
+
+	metricsAddr := envOrDefault("METRICS_ADDR", ":2112")
+	go func() {
+		if err := metrics.Serve(metricsAddr); err != nil {
+			logger.Error("metrics server stopped", "error", err.Error())
+		}
+	}()
+
 	bot := slacker.NewClient(os.Getenv("SLACK_BOT_TOKEN"), os.Getenv("SLACK_APP_TOKEN")) // to get access token use https://api.slack.com/custom-integrations/legacy-tokens
-	client := witai.NewClient(os.Getenv("WIT_AI_TOKEN"))                                 // to get access token use https://wit.ai
-	wolframClient = &wolfram.Client{AppID: os.Getenv("WOLFRAM_APP_ID")}                  // to get access token use https://www.wolframalpha.com/
-	go printCommandEvents(bot.CommandEvents())                                           // print function from slacker package to get command events
+	go logCommandEvents(logger, bot.CommandEvents())                                     // structured log of every command invocation
 
-	bot.Command("query for bot - <message>", &slacker.CommandDefinition{
-		Description: "send any question to wolfram",
-		Example:     "who is the president of india",
-		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
-			query := request.Param("message") // user to slack message
+	pipe, err := pipeline.New() // NLU + answer providers, selected via NLU_PROVIDER/ANSWER_PROVIDER env vars
+	if err != nil {
+		log.Fatalf("error building pipeline: %v", err)
+	}
 
-			msg, err := client.Parse(&witai.MessageRequest{ // slack to wit.ai message
-				Query: query,
-			})
+	store, err := memory.NewBoltStore(envOrDefault("CONVERSATION_DB", "conversations.db"))
+	if err != nil {
+		log.Fatalf("error opening conversation store: %v", err)
+	}
+	defer store.Close()
+
+	quotas, err := authz.NewQuotaStore(envOrDefault("QUOTA_DB", "quotas.db"))
+	if err != nil {
+		log.Fatalf("error opening quota store: %v", err)
+	}
+	defer quotas.Close()
+
+	limiter := authz.NewRateLimiter(
+		envOrDefaultFloat("RATE_LIMIT_USER_RPS", 0.5),
+		envOrDefaultInt("RATE_LIMIT_USER_BURST", 3),
+		envOrDefaultFloat("RATE_LIMIT_CHANNEL_RPS", 2),
+		envOrDefaultInt("RATE_LIMIT_CHANNEL_BURST", 10),
+	)
+	guard := authz.NewGuard(limiter, quotas, envOrDefaultInt("DAILY_QUOTA", 200))
+
+	// Commands come entirely from the YAML config so operators can add or
+	// retune wolfram-backed commands without recompiling. Adding/removing a
+	// command still requires a restart since slacker compiles usage patterns
+	// in at registration time; see config.Store for what SIGHUP can change.
+	// Every command shares the same AuthorizationFunc so allow/deny lists,
+	// rate limits, and quotas are enforced uniformly instead of per-handler.
+	for _, cmd := range configStore.Get().Commands {
+		bot.Command(cmd.Usage, &slacker.CommandDefinition{
+			Description:       cmd.Description,
+			Example:           cmd.Example,
+			AuthorizationFunc: authorizationFunc(configStore, guard, logger, cmd.Usage),
+			Handler:           queryHandler(configStore, pipe, store, logger, cmd.Usage),
+		})
+	}
+
+	bot.Command("quota", &slacker.CommandDefinition{
+		Description:       "show how many commands you have left today",
+		Example:           "quota",
+		AuthorizationFunc: builtinAuthorizationFunc(guard, logger, "quota"),
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			user := botCtx.Event().User
+			remaining, unlimited, err := guard.Remaining(user)
 			if err != nil {
-				log.Printf("error calling Wit.ai: %v", err)
-				response.Reply("Sorry, I'm having trouble understanding right now.")
+				logger.Error("error reading quota", "error", err.Error())
+				response.Reply("Sorry, I couldn't look up your quota.", slacker.WithThreadReply(true))
 				return
 			}
+			if unlimited {
+				response.Reply("You have no daily quota configured, so no limit applies.", slacker.WithThreadReply(true))
+				return
+			}
+			response.Reply(fmt.Sprintf("You have %d commands left today.", remaining), slacker.WithThreadReply(true))
+		},
+	})
+
+	bot.Command("history", &slacker.CommandDefinition{
+		Description:       "show the last questions and answers in this thread",
+		Example:           "history",
+		AuthorizationFunc: builtinAuthorizationFunc(guard, logger, "history"),
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			channel, user, threadTS := conversationKey(botCtx)
 
-			data, err := json.MarshalIndent(msg, "", "    ") // convert to json
+			history, err := store.History(channel, user, threadTS)
 			if err != nil {
-				log.Printf("error marshalling wit.ai response: %v", err)
-				response.Reply("Sorry, I'm having trouble processing the response.")
+				logger.Error("error reading conversation history", "error", err.Error())
+				response.Reply("Sorry, I couldn't look up the history for this thread.", slacker.WithThreadReply(true))
+				return
+			}
+			if len(history) == 0 {
+				response.Reply("I don't have any history for this thread yet.", slacker.WithThreadReply(true))
 				return
 			}
 
-			rough := string(data[:])
-			value := gjson.Get(rough, "entities.wit$wolfram_search_query:wolfram_search_query.0.value")
-			answer := query // Fallback to the original query
-			if value.Exists() {
-				answer = value.String()
+			var builder strings.Builder
+			for _, turn := range history {
+				builder.WriteString(fmt.Sprintf("*Q:* %s\n*A:* %s\n", turn.Query, turn.Answer))
 			}
+			response.Reply(builder.String(), slacker.WithThreadReply(true))
+		},
+	})
 
-			res, err := wolframClient.GetSpokentAnswerQuery(answer, wolfram.Metric, 1000)
-			if err != nil {
-				log.Printf("wolfram query failed: %v", err)
-				response.Reply("Sorry, I couldn't get an answer from Wolfram Alpha.")
+	bot.Command("forget", &slacker.CommandDefinition{
+		Description:       "forget the conversation history for this thread",
+		Example:           "forget",
+		AuthorizationFunc: builtinAuthorizationFunc(guard, logger, "forget"),
+		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+			channel, user, threadTS := conversationKey(botCtx)
+
+			if err := store.Forget(channel, user, threadTS); err != nil {
+				logger.Error("error forgetting conversation history", "error", err.Error())
+				response.Reply("Sorry, I couldn't forget this thread's history.", slacker.WithThreadReply(true))
 				return
 			}
-			response.Reply(res)
+			response.Reply("Done, I've forgotten this thread's history.", slacker.WithThreadReply(true))
 		},
 	})
 
-	bot.Command("full query for bot - <message>", &slacker.CommandDefinition{
-		Description: "send any question to wolfram for a full report",
-		Example:     "weather in new york",
-		Handler: func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
-			query := request.Param("message")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-			// Let the user know we're working on it
-			response.Reply("Thinking...")
+	err = bot.Listen(ctx)
 
-			msg, err := client.Parse(&witai.MessageRequest{
-				Query: query,
-			})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// queryHandler builds a slacker handler for a config-driven command. It looks
+// up the command's CommandConfig by usage on every invocation, so SIGHUP
+// reloads of authorization lists and templates take effect immediately.
+func queryHandler(configStore *config.Store, pipe *pipeline.Pipeline, store memory.ConversationStore, logger *slog.Logger, usage string) func(slacker.BotContext, slacker.Request, slacker.ResponseWriter) {
+	return func(botCtx slacker.BotContext, request slacker.Request, response slacker.ResponseWriter) {
+		start := time.Now()
+		channel, user, threadTS := conversationKey(botCtx)
+
+		var err error
+		var errClass string
+		metrics.InFlightRequests.Inc()
+		defer func() {
+			metrics.InFlightRequests.Dec()
+			status := "ok"
 			if err != nil {
-				log.Printf("error calling Wit.ai: %v", err)
-				response.Reply("Sorry, I'm having trouble understanding right now.")
-				return
+				status = "error"
 			}
+			metrics.CommandInvocations.WithLabelValues(usage, status).Inc()
+			logging.LogCommandResult(logger, usage, user, channel, time.Since(start), errClass, err)
+		}()
 
-			data, _ := json.MarshalIndent(msg, "", "    ")
-			rough := string(data[:])
-			value := gjson.Get(rough, "entities.wit$wolfram_search_query:wolfram_search_query.0.value")
-			answer := query // Fallback to the original query
-			if value.Exists() {
-				answer = value.String()
-			}
+		cmd, ok := configStore.Get().Command(usage)
+		if !ok {
+			err = fmt.Errorf("command %q is no longer configured", usage)
+			errClass = "config_error"
+			response.Reply("Sorry, this command isn't configured anymore.", slacker.WithThreadReply(true))
+			return
+		}
+		history, historyErr := store.History(channel, user, threadTS)
+		if historyErr != nil {
+			logger.Warn("error reading conversation history", "error", historyErr.Error())
+		}
+		rewritten := memory.RewriteQuery(history, request.Param("message"))
+
+		var result *pipeline.NLUResult
+		nluStart := time.Now()
+		result, err = pipe.NLU.Parse(rewritten)
+		metrics.ObserveProvider(pipe.NLU.Name(), "parse", nluStart, err)
+		if err != nil {
+			errClass = "nlu_error"
+			response.Reply("Sorry, I'm having trouble understanding right now.", slacker.WithThreadReply(true))
+			return
+		}
+		if cmd.RequiredIntent != "" && result.Intent != cmd.RequiredIntent {
+			response.Reply(fmt.Sprintf("Sorry, that doesn't look like a %s question.", cmd.RequiredIntent), slacker.WithThreadReply(true))
+			return
+		}
+
+		entities := make(map[string]string, len(result.Entities)+2)
+		for k, v := range result.Entities {
+			entities[k] = v
+		}
+		entities["message"] = rewritten
+		entities["query"] = result.Query
 
-			// Use GetQueryResult for a full, structured response
-			res, err := wolframClient.GetQueryResult(answer, nil)
+		var query string
+		query, err = cmd.RenderQuery(entities)
+		if err != nil {
+			errClass = "template_error"
+			response.Reply("Sorry, I couldn't build a query for that.", slacker.WithThreadReply(true))
+			return
+		}
+		if query == "" {
+			query = result.Query
+		}
+
+		if !cmd.Full {
+			var answer string
+			answer, err = pipe.AnswerWithFallback(query)
 			if err != nil {
-				log.Printf("wolfram full query failed: %v", err)
-				response.Reply("Sorry, I couldn't get a full report from Wolfram Alpha.")
+				errClass = "answer_error"
+				response.Reply("Sorry, I couldn't get an answer for that.", slacker.WithThreadReply(true))
 				return
 			}
+			response.Reply(answer, slacker.WithThreadReply(true))
+			recordTurn(logger, store, channel, user, threadTS, rewritten, answer)
+			return
+		}
+
+		response.Reply("Thinking...", slacker.WithThreadReply(true))
 
-			if res.Success == "false" || len(res.Pods) < 2 {
-				response.Reply("Sorry, Wolfram Alpha couldn't find an answer for that.")
+		wolframAnswer, ok := pipe.Primary.(pipeline.FullAnswerProvider)
+		if !ok {
+			// Non-wolfram primary providers don't have pods to report on;
+			// fall back to the plain answer chain instead.
+			var answer string
+			answer, err = pipe.AnswerWithFallback(query)
+			if err != nil {
+				errClass = "answer_error"
+				response.Reply("Sorry, I couldn't get a full report for that.", slacker.WithThreadReply(true))
 				return
 			}
+			response.Reply(answer, slacker.WithThreadReply(true))
+			recordTurn(logger, store, channel, user, threadTS, rewritten, answer)
+			return
+		}
 
-			// The first pod is usually the input interpretation, the second is the primary result.
-			// We will format and return the text from the second pod.
-			// For a more advanced bot, you could iterate through all pods.
-			pod := res.Pods[1]
-			var builder strings.Builder
-			builder.WriteString(fmt.Sprintf("*%s*\n", pod.Title))
-			builder.WriteString(fmt.Sprintf("```%s```", pod.SubPods[0].Plaintext))
+		var res *wolfram.QueryResult
+		fullStart := time.Now()
+		res, err = wolframAnswer.FullAnswer(query)
+		metrics.ObserveProvider(wolframAnswer.Name(), "full_answer", fullStart, err)
+		if err != nil {
+			errClass = "answer_error"
+			response.Reply("Sorry, I couldn't get a full report from Wolfram Alpha.", slacker.WithThreadReply(true))
+			return
+		}
+		if res.Success == "false" || len(res.Pods) < 2 {
+			response.Reply("Sorry, Wolfram Alpha couldn't find an answer for that.", slacker.WithThreadReply(true))
+			return
+		}
 
-			response.Reply(builder.String())
-		},
-	})
+		blocks := render.PodBlocks(botCtx.Client(), channel, res.Pods)
+		response.Reply("", slacker.WithBlocks(blocks), slacker.WithThreadReply(true))
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+		// The second pod is usually the primary result; that's what we keep
+		// for follow-up rewriting since pods themselves aren't plain text.
+		// Fall back to its title if it has no subpods to pull plaintext from.
+		pod := res.Pods[1]
+		answer := pod.Title
+		if len(pod.SubPods) > 0 {
+			answer = pod.SubPods[0].Plaintext
+		}
+		recordTurn(logger, store, channel, user, threadTS, rewritten, answer)
+	}
+}
+
+// authorizationFunc builds a slacker AuthorizationFunc for a config-driven
+// command, combining its allow/deny list with rate limiting and daily
+// quotas via guard. slacker only shows the user a generic "unauthorized"
+// message on rejection, so the specific reason is logged here instead.
+func authorizationFunc(configStore *config.Store, guard *authz.Guard, logger *slog.Logger, usage string) func(slacker.BotContext, slacker.Request) bool {
+	return func(botCtx slacker.BotContext, request slacker.Request) bool {
+		event := botCtx.Event()
+		cmd, ok := configStore.Get().Command(usage)
+		if !ok {
+			return false
+		}
 
-	err := bot.Listen(ctx)
+		allowed, reason := guard.Allow(cmd, event.User, event.Channel)
+		if !allowed {
+			logger.Warn("command rejected", "command", usage, "user", event.User, "channel", event.Channel, "reason", reason)
+		}
+		return allowed
+	}
+}
+
+// builtinAuthorizationFunc builds a slacker AuthorizationFunc for a command
+// that isn't declared in the YAML config (quota/history/forget), so the
+// same rate limiting and daily quota enforcement applies uniformly across
+// every command instead of just the config-driven ones. These commands
+// have no allow/deny list of their own, so they're checked against an
+// unrestricted CommandConfig.
+func builtinAuthorizationFunc(guard *authz.Guard, logger *slog.Logger, usage string) func(slacker.BotContext, slacker.Request) bool {
+	cmd := &config.CommandConfig{Usage: usage}
+	return func(botCtx slacker.BotContext, request slacker.Request) bool {
+		event := botCtx.Event()
+		allowed, reason := guard.Allow(cmd, event.User, event.Channel)
+		if !allowed {
+			logger.Warn("command rejected", "command", usage, "user", event.User, "channel", event.Channel, "reason", reason)
+		}
+		return allowed
+	}
+}
 
+// conversationKey pulls the (channel, user, thread) triple a ConversationStore
+// is keyed by out of a command's BotContext. Messages that aren't already in
+// a thread are keyed by their own timestamp, so they start a new thread of
+// their own the first time they're followed up on.
+func conversationKey(botCtx slacker.BotContext) (channel, user, threadTS string) {
+	event := botCtx.Event()
+	threadTS = event.ThreadTimeStamp
+	if threadTS == "" {
+		threadTS = event.TimeStamp
+	}
+	return event.Channel, event.User, threadTS
+}
+
+// recordTurn saves a query/answer pair, logging rather than failing the
+// request if the store write fails.
+func recordTurn(logger *slog.Logger, store memory.ConversationStore, channel, user, threadTS, query, answer string) {
+	turn := memory.Turn{Query: query, Answer: answer, Timestamp: time.Now()}
+	if err := store.Append(channel, user, threadTS, turn); err != nil {
+		logger.Error("error recording conversation turn", "error", err.Error())
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envOrDefaultInt parses key as an int, falling back to def if it's unset
+// or invalid.
+func envOrDefaultInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
 	if err != nil {
-		log.Fatal(err)
+		return def
+	}
+	return v
+}
+
+// envOrDefaultFloat parses key as a float64, falling back to def if it's
+// unset or invalid.
+func envOrDefaultFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
 	}
+	return v
 }
@@ -0,0 +1,67 @@
+package authz
+
+import (
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/config"
+)
+
+// Guard combines a command's allow/deny list with rate limiting and a daily
+// per-user quota into the single check main.go installs as every command's
+// AuthorizationFunc.
+type Guard struct {
+	limiter    *RateLimiter
+	quotas     *QuotaStore
+	dailyLimit int
+}
+
+// NewGuard builds a Guard enforcing limiter's rate limits and a dailyLimit
+// quota tracked in quotas. A dailyLimit of 0 means unlimited.
+func NewGuard(limiter *RateLimiter, quotas *QuotaStore, dailyLimit int) *Guard {
+	return &Guard{limiter: limiter, quotas: quotas, dailyLimit: dailyLimit}
+}
+
+// Allow reports whether user in channel may invoke cmd right now, and if
+// not, a short reason ("denied", "rate_limited", "quota_exceeded", or
+// "quota_error" if the usage count can't be recorded)
+// callers can log without exposing details to the user. On success, it
+// counts the invocation against user's daily quota.
+func (g *Guard) Allow(cmd *config.CommandConfig, user, channel string) (ok bool, reason string) {
+	if !cmd.IsAuthorized(user, channel) {
+		return false, "denied"
+	}
+	if !g.limiter.Allow(user, channel) {
+		return false, "rate_limited"
+	}
+	if g.dailyLimit > 0 {
+		used, err := g.quotas.Used(user)
+		if err == nil && used >= g.dailyLimit {
+			return false, "quota_exceeded"
+		}
+	}
+	if _, err := g.quotas.Increment(user); err != nil {
+		return false, "quota_error"
+	}
+	return true, ""
+}
+
+// Remaining reports how many commands user may still run today, never less
+// than 0. With a dailyLimit of 0 it reports unlimited as true and remaining
+// as 0.
+func (g *Guard) Remaining(user string) (remaining int, unlimited bool, err error) {
+	if g.dailyLimit == 0 {
+		return 0, true, nil
+	}
+	used, err := g.quotas.Used(user)
+	if err != nil {
+		return 0, false, err
+	}
+	remaining = g.dailyLimit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, false, nil
+}
+
+// Close releases the Guard's underlying quota store.
+func (g *Guard) Close() error {
+	return g.quotas.Close()
+}
@@ -0,0 +1,128 @@
+package authz
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/config"
+)
+
+func newTestGuard(t *testing.T, dailyLimit int) *Guard {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "quotas.db")
+	quotas, err := NewQuotaStore(path)
+	if err != nil {
+		t.Fatalf("NewQuotaStore: %v", err)
+	}
+	t.Cleanup(func() { quotas.Close() })
+
+	limiter := NewRateLimiter(0, 10, 0, 10)
+	return NewGuard(limiter, quotas, dailyLimit)
+}
+
+func TestGuardAllow(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmd        *config.CommandConfig
+		user       string
+		channel    string
+		wantAllow  bool
+		wantReason string
+	}{
+		{
+			name:       "unrestricted command allowed",
+			cmd:        &config.CommandConfig{},
+			user:       "u1",
+			channel:    "c1",
+			wantAllow:  true,
+			wantReason: "",
+		},
+		{
+			name:       "user not on allow list is denied",
+			cmd:        &config.CommandConfig{AllowedUsers: []string{"someone-else"}},
+			user:       "u1",
+			channel:    "c1",
+			wantAllow:  false,
+			wantReason: "denied",
+		},
+		{
+			name:       "channel not on allow list is denied",
+			cmd:        &config.CommandConfig{AllowedChannels: []string{"other-channel"}},
+			user:       "u1",
+			channel:    "c1",
+			wantAllow:  false,
+			wantReason: "denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := newTestGuard(t, 0)
+			allowed, reason := guard.Allow(tt.cmd, tt.user, tt.channel)
+			if allowed != tt.wantAllow || reason != tt.wantReason {
+				t.Errorf("Allow() = (%v, %q), want (%v, %q)", allowed, reason, tt.wantAllow, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestGuardAllowQuotaExceeded(t *testing.T) {
+	guard := newTestGuard(t, 2)
+	cmd := &config.CommandConfig{}
+
+	for i := 0; i < 2; i++ {
+		if allowed, reason := guard.Allow(cmd, "u1", "c1"); !allowed {
+			t.Fatalf("call %d: expected allowed, got rejected with reason %q", i, reason)
+		}
+	}
+
+	allowed, reason := guard.Allow(cmd, "u1", "c1")
+	if allowed || reason != "quota_exceeded" {
+		t.Errorf("Allow() = (%v, %q), want (false, %q)", allowed, reason, "quota_exceeded")
+	}
+
+	// A different user's own quota is untouched by u1 exhausting theirs.
+	if allowed, reason := guard.Allow(cmd, "u2", "c1"); !allowed {
+		t.Errorf("expected u2 to be allowed, got rejected with reason %q", reason)
+	}
+}
+
+func TestQuotaStoreDayRollover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotas.db")
+	store, err := NewQuotaStore(path)
+	if err != nil {
+		t.Fatalf("NewQuotaStore: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Increment("u1"); err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	used, err := store.Used("u1")
+	if err != nil {
+		t.Fatalf("Used: %v", err)
+	}
+	if used != 1 {
+		t.Fatalf("Used() = %d, want 1", used)
+	}
+
+	// Simulate usage recorded on a past day: it must not count toward
+	// today's total, since each day's bucket resets independently.
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quotasBucket)
+		return bucket.Put(quotaKey("u1", "2000-01-01"), itob(99))
+	})
+	if err != nil {
+		t.Fatalf("seeding past-day usage: %v", err)
+	}
+
+	used, err = store.Used("u1")
+	if err != nil {
+		t.Fatalf("Used: %v", err)
+	}
+	if used != 1 {
+		t.Errorf("Used() = %d after seeding a past day, want 1 (today's count unaffected)", used)
+	}
+}
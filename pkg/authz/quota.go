@@ -0,0 +1,89 @@
+package authz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var quotasBucket = []byte("quotas")
+
+// QuotaStore tracks how many commands each user has run on a given day,
+// persisted so quotas survive a bot restart instead of resetting for free.
+type QuotaStore struct {
+	db *bolt.DB
+}
+
+// NewQuotaStore opens (creating if necessary) a BoltDB-backed QuotaStore at
+// path.
+func NewQuotaStore(path string) (*QuotaStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening quota store at %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(quotasBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating quotas bucket: %w", err)
+	}
+
+	return &QuotaStore{db: db}, nil
+}
+
+// today returns the UTC calendar day used as the quota reset boundary.
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+func quotaKey(user, day string) []byte {
+	return []byte(user + "|" + day)
+}
+
+// Increment records one more command usage for user on the current day and
+// returns the new count for that day.
+func (s *QuotaStore) Increment(user string) (int, error) {
+	var count int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quotasBucket)
+		key := quotaKey(user, today())
+
+		count = readCount(bucket, key) + 1
+		return bucket.Put(key, itob(count))
+	})
+	return count, err
+}
+
+// Used returns how many commands user has run on the current day.
+func (s *QuotaStore) Used(user string) (int, error) {
+	var count int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = readCount(tx.Bucket(quotasBucket), quotaKey(user, today()))
+		return nil
+	})
+	return count, err
+}
+
+// Close releases the underlying BoltDB file.
+func (s *QuotaStore) Close() error {
+	return s.db.Close()
+}
+
+func readCount(bucket *bolt.Bucket, key []byte) int {
+	data := bucket.Get(key)
+	if data == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(data))
+}
+
+func itob(v int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(v))
+	return buf
+}
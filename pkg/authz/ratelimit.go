@@ -0,0 +1,78 @@
+// Package authz enforces per-user/per-channel rate limits and daily quotas
+// on top of slacker's authorization hook, since every command in this bot
+// eventually costs a billed Wit.ai or Wolfram Alpha API call.
+package authz
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter enforces independent token-bucket limits per user and per
+// channel, so one chatty user or channel can't exhaust the other's budget.
+type RateLimiter struct {
+	perUserRate     rate.Limit
+	perUserBurst    int
+	perChannelRate  rate.Limit
+	perChannelBurst int
+
+	mu       sync.Mutex
+	users    map[string]*rate.Limiter
+	channels map[string]*rate.Limiter
+}
+
+// NewRateLimiter builds a RateLimiter allowing perUserRPS requests per
+// second (burst perUserBurst) for any single user, and perChannelRPS
+// (burst perChannelBurst) for any single channel.
+func NewRateLimiter(perUserRPS float64, perUserBurst int, perChannelRPS float64, perChannelBurst int) *RateLimiter {
+	return &RateLimiter{
+		perUserRate:     rate.Limit(perUserRPS),
+		perUserBurst:    perUserBurst,
+		perChannelRate:  rate.Limit(perChannelRPS),
+		perChannelBurst: perChannelBurst,
+		users:           map[string]*rate.Limiter{},
+		channels:        map[string]*rate.Limiter{},
+	}
+}
+
+// Allow reports whether a request from user in channel should proceed. It
+// consumes one token from both the user's and the channel's bucket, so a
+// caller only needs to check the combined result.
+func (r *RateLimiter) Allow(user, channel string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userLimiter, ok := r.users[user]
+	if !ok {
+		userLimiter = rate.NewLimiter(r.perUserRate, r.perUserBurst)
+		r.users[user] = userLimiter
+	}
+
+	channelLimiter, ok := r.channels[channel]
+	if !ok {
+		channelLimiter = rate.NewLimiter(r.perChannelRate, r.perChannelBurst)
+		r.channels[channel] = channelLimiter
+	}
+
+	// Reserve from both buckets up front and roll back both reservations if
+	// either is exhausted, so a rejection never leaves just one bucket drained.
+	now := time.Now()
+	userRes := userLimiter.ReserveN(now, 1)
+	channelRes := channelLimiter.ReserveN(now, 1)
+
+	userOK := userRes.OK() && userRes.Delay() == 0
+	channelOK := channelRes.OK() && channelRes.Delay() == 0
+
+	if userOK && channelOK {
+		return true
+	}
+	if userRes.OK() {
+		userRes.CancelAt(now)
+	}
+	if channelRes.OK() {
+		channelRes.CancelAt(now)
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package authz
+
+import "testing"
+
+func TestRateLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name            string
+		perUserBurst    int
+		perChannelBurst int
+		calls           int
+		wantAllowed     int
+	}{
+		{
+			name:            "user burst exhausted before channel",
+			perUserBurst:    1,
+			perChannelBurst: 10,
+			calls:           3,
+			wantAllowed:     1,
+		},
+		{
+			name:            "channel burst exhausted before user",
+			perUserBurst:    10,
+			perChannelBurst: 1,
+			calls:           3,
+			wantAllowed:     1,
+		},
+		{
+			name:            "both have headroom",
+			perUserBurst:    5,
+			perChannelBurst: 5,
+			calls:           3,
+			wantAllowed:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(0, tt.perUserBurst, 0, tt.perChannelBurst)
+
+			allowed := 0
+			for i := 0; i < tt.calls; i++ {
+				if limiter.Allow("user-1", "channel-1") {
+					allowed++
+				}
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("got %d allowed calls, want %d", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+// TestRateLimiterAllowRollback verifies that a rejection caused by one
+// bucket being exhausted doesn't also drain a token from the other bucket,
+// per the rollback behavior RateLimiter.Allow documents.
+func TestRateLimiterAllowRollback(t *testing.T) {
+	t.Run("channel exhausted leaves user bucket undrained", func(t *testing.T) {
+		limiter := NewRateLimiter(0, 10, 0, 1)
+
+		if !limiter.Allow("user-1", "channel-1") {
+			t.Fatal("first call should be allowed")
+		}
+		// channel-1's single token is gone; user-2 sharing that channel
+		// should be rejected without losing a token of its own.
+		if limiter.Allow("user-2", "channel-1") {
+			t.Fatal("second call should be rejected: channel bucket is exhausted")
+		}
+		if !limiter.Allow("user-2", "channel-2") {
+			t.Error("user-2's own bucket should still have its token after the channel-1 rejection")
+		}
+	})
+
+	t.Run("user exhausted leaves channel bucket undrained", func(t *testing.T) {
+		limiter := NewRateLimiter(0, 1, 0, 10)
+
+		if !limiter.Allow("user-1", "channel-1") {
+			t.Fatal("first call should be allowed")
+		}
+		if limiter.Allow("user-1", "channel-2") {
+			t.Fatal("second call should be rejected: user bucket is exhausted")
+		}
+		if !limiter.Allow("user-2", "channel-2") {
+			t.Error("channel-2's own bucket should still have its token after the user-1 rejection")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+// Package config loads the bot's commands from a YAML file so operators can
+// add or tune a command's description, authorization, and Wolfram query
+// template without recompiling.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommandConfig describes one bot command as declared in the YAML config
+// file.
+type CommandConfig struct {
+	// Usage is the slacker command pattern, e.g. "weather in <city>".
+	Usage string `yaml:"usage"`
+
+	Description string `yaml:"description"`
+	Example     string `yaml:"example"`
+
+	// RequiredIntent, if set, must match the Wit.ai intent extracted from the
+	// message for this command to answer. Empty means any intent matches.
+	RequiredIntent string `yaml:"required_intent"`
+
+	// WolframTemplate is a Go text/template string rendered against the
+	// entities extracted by the NLU provider to build the query sent to the
+	// answer provider. Empty means the extracted query is used as-is.
+	WolframTemplate string `yaml:"wolfram_template"`
+
+	// Full selects the full, pod-by-pod report instead of a single spoken
+	// answer.
+	Full bool `yaml:"full"`
+
+	// AllowedUsers and AllowedChannels restrict who can invoke this command.
+	// Empty means unrestricted.
+	AllowedUsers    []string `yaml:"allowed_users"`
+	AllowedChannels []string `yaml:"allowed_channels"`
+}
+
+// Config is the top-level shape of the bot's YAML config file.
+type Config struct {
+	Commands []CommandConfig `yaml:"commands"`
+}
+
+// Command returns the command config registered under usage, if any. It's
+// used by handlers to pick up authorization/template changes from a
+// reloaded Config without needing to re-register the command with slacker.
+func (c *Config) Command(usage string) (*CommandConfig, bool) {
+	for i := range c.Commands {
+		if c.Commands[i].Usage == usage {
+			return &c.Commands[i], true
+		}
+	}
+	return nil, false
+}
+
+// Load reads and parses the config file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	for _, cmd := range cfg.Commands {
+		if cmd.Usage == "" {
+			return nil, fmt.Errorf("config %s: command with empty usage", path)
+		}
+	}
+	return &cfg, nil
+}
+
+// IsAuthorized reports whether userID in channelID may invoke this command.
+// An empty allow list means everyone/every channel is allowed.
+func (c *CommandConfig) IsAuthorized(userID, channelID string) bool {
+	return matchesAllowList(c.AllowedUsers, userID) && matchesAllowList(c.AllowedChannels, channelID)
+}
+
+func matchesAllowList(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderQuery builds the answer-provider query for this command from the
+// entities the NLU provider extracted. If no WolframTemplate is configured,
+// entities["query"] is returned unchanged.
+func (c *CommandConfig) RenderQuery(entities map[string]string) (string, error) {
+	if c.WolframTemplate == "" {
+		return entities["query"], nil
+	}
+
+	tmpl, err := template.New(c.Usage).Parse(c.WolframTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing wolfram_template for %q: %w", c.Usage, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, entities); err != nil {
+		return "", fmt.Errorf("rendering wolfram_template for %q: %w", c.Usage, err)
+	}
+	return buf.String(), nil
+}
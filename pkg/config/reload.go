@@ -0,0 +1,65 @@
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// Store holds a Config that can be swapped out at runtime, so a SIGHUP can
+// refresh authorization lists, templates, required intents, and the full
+// flag without restarting the bot. Registering brand new commands, or
+// changing a command's description or example, still requires a restart,
+// since slacker's usage patterns and help text are fixed at registration
+// time.
+type Store struct {
+	path string
+	cfg  atomic.Pointer[Config]
+}
+
+// NewStore loads path into a Store.
+func NewStore(path string) (*Store, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path}
+	s.cfg.Store(cfg)
+	return s, nil
+}
+
+// Get returns the current Config. Safe for concurrent use.
+func (s *Store) Get() *Config {
+	return s.cfg.Load()
+}
+
+// Reload re-reads the config file and swaps it in, leaving the previous
+// Config in place if the file is missing or invalid.
+func (s *Store) Reload() error {
+	cfg, err := Load(s.path)
+	if err != nil {
+		return err
+	}
+	s.cfg.Store(cfg)
+	return nil
+}
+
+// WatchSIGHUP reloads the Store every time the process receives SIGHUP,
+// logging the outcome. It runs until the process exits.
+func (s *Store) WatchSIGHUP() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			if err := s.Reload(); err != nil {
+				log.Printf("config: SIGHUP reload of %s failed: %v", s.path, err)
+				continue
+			}
+			log.Printf("config: reloaded %s", s.path)
+		}
+	}()
+}
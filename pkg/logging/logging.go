@@ -0,0 +1,54 @@
+// Package logging sets up the bot's structured, JSON logging so log lines
+// can be queried by command, user, latency, and error class instead of
+// grepped out of free-form text.
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/shomali11/slacker"
+)
+
+// New builds a JSON slog.Logger writing to stdout. The level is "info"
+// unless LOG_LEVEL=debug is set.
+func New() *slog.Logger {
+	level := slog.LevelInfo
+	if os.Getenv("LOG_LEVEL") == "debug" {
+		level = slog.LevelDebug
+	}
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(handler)
+}
+
+// LogCommandEvent logs a slacker CommandEvent with the fields dashboards and
+// alerts key off: command, user, and channel.
+func LogCommandEvent(logger *slog.Logger, event *slacker.CommandEvent) {
+	attrs := []any{
+		"command", event.Command,
+		"timestamp", event.Timestamp,
+	}
+	if event.Event != nil {
+		attrs = append(attrs, "user", event.Event.User, "channel", event.Event.Channel)
+	}
+	logger.Info("command event", attrs...)
+}
+
+// LogCommandResult logs the outcome of a command handler, including latency
+// and, on failure, an error class used to group related failures (e.g.
+// "nlu_error", "answer_error") without needing the full error text.
+func LogCommandResult(logger *slog.Logger, command, user, channel string, latency time.Duration, errClass string, err error) {
+	attrs := []any{
+		"command", command,
+		"user", user,
+		"channel", channel,
+		"latency_ms", latency.Milliseconds(),
+	}
+	if err == nil {
+		logger.Info("command completed", attrs...)
+		return
+	}
+	attrs = append(attrs, "error_class", errClass, "error", err.Error())
+	logger.Error("command failed", attrs...)
+}
@@ -0,0 +1,29 @@
+package memory
+
+import "strings"
+
+// followUpPrefixes are the leading words that mark a message as a follow-up
+// to the prior turn rather than a fresh, self-contained question.
+var followUpPrefixes = []string{
+	"and ", "what about ", "how about ", "what if ", "also ",
+}
+
+// RewriteQuery turns a follow-up message like "and in Celsius?" into a
+// self-contained query by combining it with the most recent turn's query,
+// e.g. "weather in berlin and in Celsius?". If query doesn't look like a
+// follow-up, or there's no history yet, it's returned unchanged.
+func RewriteQuery(history []Turn, query string) string {
+	if len(history) == 0 {
+		return query
+	}
+
+	trimmed := strings.TrimSpace(query)
+	lower := strings.ToLower(trimmed)
+	for _, prefix := range followUpPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			last := history[len(history)-1]
+			return last.Query + " " + trimmed
+		}
+	}
+	return query
+}
@@ -0,0 +1,127 @@
+// Package memory gives the bot a short per-thread conversation history so
+// users can ask follow-up questions ("and in Celsius?") without repeating
+// themselves.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxTurns caps how many prior query/answer pairs are kept per thread.
+const maxTurns = 10
+
+var conversationsBucket = []byte("conversations")
+
+// Turn is one query/answer pair in a conversation's history.
+type Turn struct {
+	Query     string    `json:"query"`
+	Answer    string    `json:"answer"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ConversationStore persists the last few turns of a conversation, keyed by
+// the Slack channel, user, and thread it took place in.
+type ConversationStore interface {
+	// Append records a turn, trimming the oldest entries beyond maxTurns.
+	Append(channel, user, threadTS string, turn Turn) error
+	// History returns the stored turns for a conversation, oldest first.
+	History(channel, user, threadTS string) ([]Turn, error)
+	// Forget clears a conversation's history.
+	Forget(channel, user, threadTS string) error
+	// Close releases any underlying resources.
+	Close() error
+}
+
+// BoltStore is a ConversationStore backed by a BoltDB file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB-backed ConversationStore
+// at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening conversation store at %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(conversationsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating conversations bucket: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func conversationKey(channel, user, threadTS string) []byte {
+	return []byte(channel + "|" + user + "|" + threadTS)
+}
+
+// Append records a turn, trimming the oldest entries beyond maxTurns.
+func (s *BoltStore) Append(channel, user, threadTS string, turn Turn) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(conversationsBucket)
+		key := conversationKey(channel, user, threadTS)
+
+		turns, err := readTurns(bucket, key)
+		if err != nil {
+			return err
+		}
+
+		turns = append(turns, turn)
+		if len(turns) > maxTurns {
+			turns = turns[len(turns)-maxTurns:]
+		}
+
+		data, err := json.Marshal(turns)
+		if err != nil {
+			return fmt.Errorf("marshalling conversation history: %w", err)
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// History returns the stored turns for a conversation, oldest first.
+func (s *BoltStore) History(channel, user, threadTS string) ([]Turn, error) {
+	var turns []Turn
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(conversationsBucket)
+		var err error
+		turns, err = readTurns(bucket, conversationKey(channel, user, threadTS))
+		return err
+	})
+	return turns, err
+}
+
+// Forget clears a conversation's history.
+func (s *BoltStore) Forget(channel, user, threadTS string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(conversationsBucket).Delete(conversationKey(channel, user, threadTS))
+	})
+}
+
+// Close releases the underlying BoltDB file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func readTurns(bucket *bolt.Bucket, key []byte) ([]Turn, error) {
+	data := bucket.Get(key)
+	if data == nil {
+		return nil, nil
+	}
+
+	var turns []Turn
+	if err := json.Unmarshal(data, &turns); err != nil {
+		return nil, fmt.Errorf("unmarshalling conversation history: %w", err)
+	}
+	return turns, nil
+}
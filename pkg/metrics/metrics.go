@@ -0,0 +1,62 @@
+// Package metrics exposes the bot's Prometheus metrics: command invocation
+// counts, NLU/answer provider latency and error rates, and in-flight
+// request gauges.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// CommandInvocations counts every command invocation by command and
+	// outcome ("ok" or "error").
+	CommandInvocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bot_command_invocations_total",
+		Help: "Number of bot command invocations.",
+	}, []string{"command", "status"})
+
+	// ProviderLatency measures how long NLU/answer provider calls take.
+	ProviderLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bot_provider_latency_seconds",
+		Help:    "Latency of NLU/answer provider calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "operation"})
+
+	// ProviderErrors counts NLU/answer provider call failures.
+	ProviderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bot_provider_errors_total",
+		Help: "Number of failed NLU/answer provider calls.",
+	}, []string{"provider", "operation"})
+
+	// InFlightRequests tracks how many command invocations are currently
+	// being handled.
+	InFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bot_inflight_requests",
+		Help: "Number of command invocations currently being handled.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(CommandInvocations, ProviderLatency, ProviderErrors, InFlightRequests)
+}
+
+// ObserveProvider records the latency of a provider call and, on failure,
+// increments its error counter.
+func ObserveProvider(provider, operation string, start time.Time, err error) {
+	ProviderLatency.WithLabelValues(provider, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ProviderErrors.WithLabelValues(provider, operation).Inc()
+	}
+}
+
+// Serve starts the /metrics HTTP endpoint on addr (e.g. ":2112"). It blocks
+// until the server stops, so callers typically run it in a goroutine.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterAnswer("llm", func() (AnswerProvider, error) {
+		host := os.Getenv("OLLAMA_HOST")
+		if host == "" {
+			host = "http://localhost:11434"
+		}
+		model := os.Getenv("OLLAMA_MODEL")
+		if model == "" {
+			model = "llama3"
+		}
+		return &llmAnswer{
+			host:   strings.TrimRight(host, "/"),
+			model:  model,
+			client: &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	})
+}
+
+// llmAnswer is a fallback AnswerProvider used when a Wolfram query returns
+// an error. It asks a local Ollama model to answer directly instead.
+type llmAnswer struct {
+	host   string
+	model  string
+	client *http.Client
+}
+
+func (l *llmAnswer) Name() string { return "llm" }
+
+func (l *llmAnswer) Answer(query string) (string, error) {
+	body, err := json.Marshal(ollamaGenerateRequest{
+		Model:  l.model,
+		Prompt: query,
+		Stream: false,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshalling ollama request: %w", err)
+	}
+
+	resp, err := l.client.Post(l.host+"/api/generate", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("calling ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var generated ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		return "", fmt.Errorf("decoding ollama response: %w", err)
+	}
+	if strings.TrimSpace(generated.Response) == "" {
+		return "", fmt.Errorf("ollama returned an empty response")
+	}
+	return generated.Response, nil
+}
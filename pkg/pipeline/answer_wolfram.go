@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/krognol/go-wolfram"
+)
+
+func init() {
+	RegisterAnswer("wolfram", func() (AnswerProvider, error) {
+		appID := os.Getenv("WOLFRAM_APP_ID")
+		if appID == "" {
+			return nil, fmt.Errorf("WOLFRAM_APP_ID is not set")
+		}
+		return &wolframAnswer{client: &wolfram.Client{AppID: appID}}, nil
+	})
+}
+
+// FullAnswerProvider is implemented by answer providers that can return a
+// full, pod-by-pod Wolfram Alpha report rather than a single string.
+// Callers that want that level of detail (e.g. the "full query" command)
+// should type-assert an AnswerProvider against this interface.
+type FullAnswerProvider interface {
+	AnswerProvider
+	FullAnswer(query string) (*wolfram.QueryResult, error)
+}
+
+// wolframAnswer answers queries via the Wolfram Alpha spoken-results API.
+type wolframAnswer struct {
+	client *wolfram.Client
+}
+
+func (w *wolframAnswer) Name() string { return "wolfram" }
+
+func (w *wolframAnswer) Answer(query string) (string, error) {
+	res, err := w.client.GetSpokentAnswerQuery(query, wolfram.Metric, 1000)
+	if err != nil {
+		return "", fmt.Errorf("wolfram query failed: %w", err)
+	}
+	return res, nil
+}
+
+// FullAnswer returns the full, structured Wolfram response so callers that
+// need pod-by-pod detail (e.g. the "full query" command) can bypass the
+// single-string AnswerProvider interface.
+func (w *wolframAnswer) FullAnswer(query string) (*wolfram.QueryResult, error) {
+	res, err := w.client.GetQueryResult(query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wolfram full query failed: %w", err)
+	}
+	return res, nil
+}
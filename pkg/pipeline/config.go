@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// New builds a Pipeline from environment configuration, so operators can
+// swap NLU or answer backends without recompiling:
+//
+//	NLU_PROVIDER      - "witai" (default) or "keyword", "llm"
+//	ANSWER_PROVIDER   - "wolfram" (default) or "llm"
+//	ANSWER_FALLBACKS  - comma-separated provider names tried in order if
+//	                    ANSWER_PROVIDER fails, e.g. "llm"
+func New() (*Pipeline, error) {
+	nluName := envOrDefault("NLU_PROVIDER", "witai")
+	answerName := envOrDefault("ANSWER_PROVIDER", "wolfram")
+
+	nlu, err := NewNLU(nluName)
+	if err != nil {
+		return nil, fmt.Errorf("building NLU provider: %w", err)
+	}
+
+	primary, err := NewAnswer(answerName)
+	if err != nil {
+		return nil, fmt.Errorf("building answer provider: %w", err)
+	}
+
+	var fallbacks []AnswerProvider
+	for _, name := range splitAndTrim(os.Getenv("ANSWER_FALLBACKS")) {
+		fallback, err := NewAnswer(name)
+		if err != nil {
+			return nil, fmt.Errorf("building fallback answer provider %q: %w", name, err)
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	return &Pipeline{NLU: nlu, Primary: primary, Fallbacks: fallbacks}, nil
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func splitAndTrim(csv string) []string {
+	if csv == "" {
+		return nil
+	}
+	parts := strings.Split(csv, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package pipeline
+
+import "strings"
+
+func init() {
+	RegisterNLU("keyword", func() (NLUProvider, error) {
+		return &keywordNLU{}, nil
+	})
+}
+
+// keywordNLU is a zero-dependency NLU provider for setups that don't want
+// to configure Wit.ai. It recognizes a handful of query shapes by keyword
+// and otherwise passes the message straight through as the query.
+type keywordNLU struct{}
+
+func (k *keywordNLU) Name() string { return "keyword" }
+
+func (k *keywordNLU) Parse(query string) (*NLUResult, error) {
+	lower := strings.ToLower(query)
+	intent := "general_query"
+	switch {
+	case strings.Contains(lower, "weather"):
+		intent = "weather"
+	case strings.Contains(lower, "convert") || strings.Contains(lower, "celsius") || strings.Contains(lower, "fahrenheit"):
+		intent = "conversion"
+	case strings.Contains(lower, "who is") || strings.Contains(lower, "who's"):
+		intent = "identity"
+	}
+
+	return &NLUResult{
+		Intent:   intent,
+		Entities: map[string]string{},
+		Query:    strings.TrimSpace(query),
+	}, nil
+}
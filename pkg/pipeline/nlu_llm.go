@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterNLU("llm", func() (NLUProvider, error) {
+		host := os.Getenv("OLLAMA_HOST")
+		if host == "" {
+			host = "http://localhost:11434"
+		}
+		model := os.Getenv("OLLAMA_MODEL")
+		if model == "" {
+			model = "llama3"
+		}
+		return &llmNLU{
+			host:   strings.TrimRight(host, "/"),
+			model:  model,
+			client: &http.Client{Timeout: 15 * time.Second},
+		}, nil
+	})
+}
+
+// llmNLU asks a local Ollama server to pull the intent and a cleaned-up
+// query out of a Slack message, the same approach used by the Ollama-backed
+// Telegram bot this pipeline is modeled on.
+type llmNLU struct {
+	host   string
+	model  string
+	client *http.Client
+}
+
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	Format string `json:"format"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+}
+
+type llmIntentExtraction struct {
+	Intent string `json:"intent"`
+	Query  string `json:"query"`
+}
+
+func (l *llmNLU) Name() string { return "llm" }
+
+func (l *llmNLU) Parse(query string) (*NLUResult, error) {
+	prompt := fmt.Sprintf(
+		"Extract the intent and the core question to ask Wolfram Alpha from this "+
+			"Slack message. Respond with JSON only, shaped as "+
+			`{"intent": "...", "query": "..."}. Message: %q`, query)
+
+	body, err := json.Marshal(ollamaGenerateRequest{
+		Model:  l.model,
+		Prompt: prompt,
+		Stream: false,
+		Format: "json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling ollama request: %w", err)
+	}
+
+	resp, err := l.client.Post(l.host+"/api/generate", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("calling ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var generated ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		return nil, fmt.Errorf("decoding ollama response: %w", err)
+	}
+
+	var extraction llmIntentExtraction
+	if err := json.Unmarshal([]byte(generated.Response), &extraction); err != nil {
+		return nil, fmt.Errorf("decoding model output %q: %w", generated.Response, err)
+	}
+
+	result := &NLUResult{
+		Intent:   extraction.Intent,
+		Entities: map[string]string{},
+		Query:    strings.TrimSpace(extraction.Query),
+	}
+	if result.Query == "" {
+		result.Query = query
+	}
+	return result, nil
+}
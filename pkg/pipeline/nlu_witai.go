@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tidwall/gjson"
+	witai "github.com/wit-ai/wit-go/v2"
+)
+
+func init() {
+	RegisterNLU("witai", func() (NLUProvider, error) {
+		token := os.Getenv("WIT_AI_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("WIT_AI_TOKEN is not set")
+		}
+		return &witaiNLU{client: witai.NewClient(token)}, nil
+	})
+}
+
+// witaiNLU calls Wit.ai to resolve a Slack message into a wolfram_search_query
+// entity, the same extraction main.go used to do inline.
+type witaiNLU struct {
+	client *witai.Client
+}
+
+func (w *witaiNLU) Name() string { return "witai" }
+
+func (w *witaiNLU) Parse(query string) (*NLUResult, error) {
+	msg, err := w.client.Parse(&witai.MessageRequest{Query: query})
+	if err != nil {
+		return nil, fmt.Errorf("calling Wit.ai: %w", err)
+	}
+
+	data, err := json.MarshalIndent(msg, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling wit.ai response: %w", err)
+	}
+
+	rough := string(data)
+	entityPath := "entities.wit$wolfram_search_query:wolfram_search_query.0.value"
+	value := gjson.Get(rough, entityPath)
+
+	result := &NLUResult{
+		Intent:   gjson.Get(rough, "intents.0.name").String(),
+		Entities: map[string]string{},
+		Query:    query,
+	}
+	if value.Exists() {
+		result.Entities["wolfram_search_query"] = value.String()
+		result.Query = value.String()
+	}
+	return result, nil
+}
@@ -0,0 +1,108 @@
+// Package pipeline wires together an NLU provider and one or more answer
+// providers so the bot can understand a message and answer it without
+// main.go needing to know which backends are actually in play.
+package pipeline
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/SR-SHREYAS/NLP_SlackBot/pkg/metrics"
+)
+
+// NLUProvider turns a raw Slack message into an intent and a set of
+// extracted entities (e.g. the cleaned-up query to send to an answer
+// provider).
+type NLUProvider interface {
+	Name() string
+	Parse(query string) (*NLUResult, error)
+}
+
+// NLUResult is the normalized output of an NLUProvider.
+type NLUResult struct {
+	Intent   string
+	Entities map[string]string
+	// Query is the best query string to hand to an AnswerProvider, after
+	// entity extraction. Falls back to the original message when the
+	// provider found nothing more specific.
+	Query string
+}
+
+// AnswerProvider answers a query produced by an NLUProvider.
+type AnswerProvider interface {
+	Name() string
+	Answer(query string) (string, error)
+}
+
+// NLUFactory builds an NLUProvider from the environment. Providers
+// register a factory under a name via RegisterNLU.
+type NLUFactory func() (NLUProvider, error)
+
+// AnswerFactory builds an AnswerProvider from the environment. Providers
+// register a factory under a name via RegisterAnswer.
+type AnswerFactory func() (AnswerProvider, error)
+
+var (
+	nluFactories    = map[string]NLUFactory{}
+	answerFactories = map[string]AnswerFactory{}
+)
+
+// RegisterNLU makes an NLU provider available under name. Implementations
+// call this from an init() function.
+func RegisterNLU(name string, factory NLUFactory) {
+	nluFactories[name] = factory
+}
+
+// RegisterAnswer makes an answer provider available under name.
+// Implementations call this from an init() function.
+func RegisterAnswer(name string, factory AnswerFactory) {
+	answerFactories[name] = factory
+}
+
+// NewNLU builds the named NLU provider.
+func NewNLU(name string) (NLUProvider, error) {
+	factory, ok := nluFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("pipeline: no NLU provider registered under %q", name)
+	}
+	return factory()
+}
+
+// NewAnswer builds the named answer provider.
+func NewAnswer(name string) (AnswerProvider, error) {
+	factory, ok := answerFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("pipeline: no answer provider registered under %q", name)
+	}
+	return factory()
+}
+
+// Pipeline glues one NLU provider to a primary answer provider plus an
+// ordered list of fallbacks that are tried in turn if the primary fails.
+type Pipeline struct {
+	NLU       NLUProvider
+	Primary   AnswerProvider
+	Fallbacks []AnswerProvider
+}
+
+// AnswerWithFallback walks the answer chain (Primary then Fallbacks in
+// order) for query, falling back to the next provider whenever one returns
+// an error. Callers are expected to have already run query through the NLU
+// provider themselves (e.g. to check an intent or render a templated
+// query), since this only runs the answer chain.
+func (p *Pipeline) AnswerWithFallback(query string) (string, error) {
+	providers := append([]AnswerProvider{p.Primary}, p.Fallbacks...)
+	var lastErr error
+	for _, provider := range providers {
+		answerStart := time.Now()
+		answer, err := provider.Answer(query)
+		metrics.ObserveProvider(provider.Name(), "answer", answerStart, err)
+		if err == nil {
+			return answer, nil
+		}
+		log.Printf("pipeline: answer provider %s failed: %v", provider.Name(), err)
+		lastErr = err
+	}
+	return "", fmt.Errorf("all answer providers failed, last error: %w", lastErr)
+}
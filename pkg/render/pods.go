@@ -0,0 +1,91 @@
+// Package render turns a Wolfram Alpha query result into a Slack Block Kit
+// message, uploading each pod's rendered image so users get proper math and
+// charts instead of ASCII approximations.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/krognol/go-wolfram"
+	"github.com/slack-go/slack"
+)
+
+// PodBlocks uploads the image of each pod's first subpod to channel as a
+// standalone file message and returns a title section block per pod; pods
+// with no subpods are skipped. Pods with no image, or whose upload fails,
+// fall back to a section block
+// containing their plaintext in a code block. Individual image
+// download/upload failures are logged rather than failing the whole
+// message.
+func PodBlocks(client *slack.Client, channel string, pods []wolfram.Pod) []slack.Block {
+	var blocks []slack.Block
+
+	for _, pod := range pods {
+		if len(pod.SubPods) == 0 {
+			continue
+		}
+		subPod := pod.SubPods[0]
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*", pod.Title), false, false),
+			nil, nil,
+		))
+
+		if subPod.Img.Src == "" {
+			blocks = append(blocks, plaintextBlock(subPod.Plaintext))
+			continue
+		}
+
+		// The image is uploaded as a standalone file message below; it
+		// already renders in-channel, so no image block is needed here.
+		// Only fall back to a plaintext block if the upload itself fails.
+		if err := uploadPodImage(client, channel, pod.Title, subPod.Img.Src); err != nil {
+			log.Printf("render: uploading image for pod %q: %v", pod.Title, err)
+			blocks = append(blocks, plaintextBlock(subPod.Plaintext))
+		}
+	}
+
+	return blocks
+}
+
+func plaintextBlock(text string) slack.Block {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("```%s```", text), false, false),
+		nil, nil,
+	)
+}
+
+// uploadPodImage downloads a pod's rendered image and uploads it to Slack so
+// it's kept alongside the conversation, not just linked from Wolfram's CDN.
+func uploadPodImage(client *slack.Client, channel, title, imgSrc string) error {
+	resp, err := http.Get(imgSrc)
+	if err != nil {
+		return fmt.Errorf("downloading pod image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pod image request returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading pod image: %w", err)
+	}
+
+	_, err = client.UploadFile(slack.FileUploadParameters{
+		Reader:   bytes.NewReader(body),
+		Filetype: "png",
+		Filename: title + ".png",
+		Title:    title,
+		Channels: []string{channel},
+	})
+	if err != nil {
+		return fmt.Errorf("uploading pod image to slack: %w", err)
+	}
+	return nil
+}